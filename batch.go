@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/golang/groupcache"
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/mapbox-search/geocodepb"
+
+	"go.opencensus.io/trace"
+)
+
+// maxBatchParallelism bounds how many distinct groupcache.Get calls a
+// single batch request can have in flight at once.
+const maxBatchParallelism = 16
+
+// batchSlot identifies one distinct cache lookup within a batch: which
+// group it belongs to and the key within that group.
+type batchSlot struct {
+	groupName string
+	key       string
+}
+
+// batchItemResult is what coalescedBatchLookup resolves each distinct slot
+// to.
+type batchItemResult struct {
+	response *mapbox.GeocodeResponse
+	err      error
+}
+
+// coalescedBatchLookup resolves every slot exactly once — duplicate
+// (groupName, key) pairs in the same batch share a single group.Get — and
+// fans the distinct lookups out across at most maxBatchParallelism
+// goroutines. Every item's fetch runs under its own child span off ctx, so
+// traces stay readable even though the work is batched.
+func coalescedBatchLookup(ctx context.Context, slots []batchSlot) []batchItemResult {
+	ctx, span := trace.StartSpan(ctx, "coalescedBatchLookup")
+	defer span.End()
+
+	unique := make(map[batchSlot]*batchItemResult, len(slots))
+	for _, s := range slots {
+		unique[s] = nil
+	}
+	// Snapshot the distinct slots before launching any goroutine: ranging
+	// over unique itself while goroutines below write back into it is a
+	// concurrent map iteration+write, which panics at runtime even though
+	// the writes are mutex-guarded — the range side isn't.
+	keys := make([]batchSlot, 0, len(unique))
+	for s := range unique {
+		keys = append(keys, s)
+	}
+
+	sem := make(chan struct{}, maxBatchParallelism)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, slot := range keys {
+		slot := slot
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			itemCtx, itemSpan := trace.StartSpan(ctx, "coalescedBatchLookup.item")
+			res := fetchSlot(itemCtx, slot)
+			itemSpan.End()
+
+			mu.Lock()
+			unique[slot] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	out := make([]batchItemResult, len(slots))
+	for i, s := range slots {
+		out[i] = *unique[s]
+	}
+	return out
+}
+
+func fetchSlot(ctx context.Context, slot batchSlot) *batchItemResult {
+	var group *groupcache.Group
+	switch slot.groupName {
+	case addressLookupName:
+		group = byNameGroup
+	case latLonLookupName:
+		group = byLatLonGroup
+	default:
+		return &batchItemResult{err: fmt.Errorf("unknown group %q", slot.groupName)}
+	}
+
+	var data []byte
+	if err := group.Get(ctx, slot.key, groupcache.AllocatingByteSliceSink(&data)); err != nil {
+		return &batchItemResult{err: err}
+	}
+	mres := new(mapbox.GeocodeResponse)
+	if err := json.Unmarshal(data, mres); err != nil {
+		return &batchItemResult{err: err}
+	}
+	return &batchItemResult{response: mres}
+}
+
+func slotForQuery(q lookupQuery) batchSlot {
+	if q.Name != "" {
+		return batchSlot{groupName: addressLookupName, key: q.Name}
+	}
+	return batchSlot{groupName: latLonLookupName, key: fmt.Sprintf("%.6f,%.6f", q.Lat, q.Lon)}
+}
+
+// batchHTTPResult is what each slot in a /batch HTTP response renders to:
+// either the geocode response or an error string, never both.
+type batchHTTPResult struct {
+	Result *mapbox.GeocodeResponse `json:"result,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+// batchHandler serves POST /batch: a JSON array of lookupQuery accepted in
+// one call, fanned out across the groupcache peers with bounded
+// parallelism and duplicate keys coalesced to a single group.Get.
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, span := trace.StartSpan(r.Context(), "batchHandler")
+	defer span.End()
+
+	var queries []lookupQuery
+	if err := parseJSON(r.Body, &queries); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slots := make([]batchSlot, len(queries))
+	for i, q := range queries {
+		slots[i] = slotForQuery(q)
+	}
+	for _, slot := range slots {
+		hotKeys.record(slot.groupName, slot.key)
+	}
+
+	results := coalescedBatchLookup(ctx, slots)
+	out := make([]batchHTTPResult, len(results))
+	for i, res := range results {
+		if res.err != nil {
+			out[i] = batchHTTPResult{Error: res.err.Error()}
+			continue
+		}
+		out[i] = batchHTTPResult{Result: res.response}
+	}
+
+	blob, err := json.Marshal(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
+
+// batchLookupGRPC backs the BatchLookup RPC with the same coalescing and
+// bounded-parallelism fan-out the HTTP /batch handler uses. Every slot gets
+// a response carrying its original index, whether it succeeded or failed,
+// so one bad address in a large batch doesn't take the rest of the stream
+// down with it.
+func batchLookupGRPC(ctx context.Context, req *geocodepb.BatchLookupRequest) []*geocodepb.BatchLookupResponse {
+	queries := make([]lookupQuery, 0, len(req.GetByName())+len(req.GetByLatLon()))
+	for _, byName := range req.GetByName() {
+		queries = append(queries, lookupQuery{Name: byName.GetName()})
+	}
+	for _, byLatLon := range req.GetByLatLon() {
+		queries = append(queries, lookupQuery{Lat: byLatLon.GetLat(), Lon: byLatLon.GetLon()})
+	}
+
+	slots := make([]batchSlot, len(queries))
+	for i, q := range queries {
+		slots[i] = slotForQuery(q)
+	}
+
+	results := coalescedBatchLookup(ctx, slots)
+	out := make([]*geocodepb.BatchLookupResponse, len(results))
+	for i, res := range results {
+		resp := &geocodepb.BatchLookupResponse{Index: int32(i)}
+		if res.err != nil {
+			resp.Error = res.err.Error()
+		} else {
+			resp.Result = toProtoGeocodeResponse(res.response)
+		}
+		out[i] = resp
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/golang/groupcache"
+	"github.com/orijtech/mapbox"
+)
+
+// TestCoalescedBatchLookupManyUniqueSlots exercises coalescedBatchLookup with
+// more unique slots than maxBatchParallelism and a getter that resolves
+// immediately, so most goroutines race to write into the shared "unique" map
+// before the dispatch loop is done enqueueing the rest. This is the shape
+// that used to panic with "concurrent map iteration and map write" when the
+// dispatch loop ranged over that same map instead of a snapshot of its keys.
+func TestCoalescedBatchLookupManyUniqueSlots(t *testing.T) {
+	byNameGroup = groupcache.NewGroup("address_lookup_batch_test", 1<<20, groupcache.GetterFunc(
+		func(ctx context.Context, key string, sink groupcache.Sink) error {
+			blob, err := json.Marshal(&mapbox.GeocodeResponse{
+				Features: []*mapbox.Feature{{PlaceName: key}},
+			})
+			if err != nil {
+				return err
+			}
+			return sink.SetBytes(blob)
+		}))
+
+	const numUnique = 32 // > maxBatchParallelism
+	const dupesPerKey = 2
+
+	var slots []batchSlot
+	for i := 0; i < numUnique; i++ {
+		key := fmt.Sprintf("place-%d", i)
+		for d := 0; d < dupesPerKey; d++ {
+			slots = append(slots, batchSlot{groupName: addressLookupName, key: key})
+		}
+	}
+
+	results := coalescedBatchLookup(context.Background(), slots)
+	if len(results) != len(slots) {
+		t.Fatalf("got %d results, want %d", len(results), len(slots))
+	}
+	for i, res := range results {
+		if res.err != nil {
+			t.Fatalf("slot %d: unexpected error: %v", i, res.err)
+		}
+		want := slots[i].key
+		if len(res.response.Features) != 1 || res.response.Features[0].PlaceName != want {
+			t.Fatalf("slot %d: got %+v, want place name %q", i, res.response, want)
+		}
+	}
+}
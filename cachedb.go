@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// diskCache is a BoltDB-backed tier between the groupcache getter and the
+// geocoder: checked on a cache miss before calling the geocoder, written
+// through with a TTL once the geocoder answers.
+type diskCache struct {
+	db  *bolt.DB
+	ttl time.Duration
+
+	hits, misses, writes uint64
+}
+
+// newDiskCache opens (creating if necessary) a BoltDB file at path and
+// ensures a bucket exists for every group name up front. Set still creates a
+// bucket that is missing, and Get treats a missing bucket as a miss.
+func newDiskCache(path string, ttl time.Duration, groupNames ...string) (*diskCache, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cachedb: failed to open %q: %v", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range groupNames {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cachedb: failed to initialize buckets: %v", err)
+	}
+	return &diskCache{db: db, ttl: ttl}, nil
+}
+
+// Get returns the cached value for groupName/key, reporting ok=false if
+// there was no entry or the entry has expired.
+func (c *diskCache) Get(groupName, key string) (value []byte, ok bool, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(groupName))
+		if b == nil {
+			return nil
+		}
+		raw := b.Get([]byte(key))
+		if raw == nil || len(raw) < 8 {
+			return nil
+		}
+		expiresAt := int64(binary.BigEndian.Uint64(raw[:8]))
+		if time.Now().Unix() > expiresAt {
+			return nil
+		}
+		value = append([]byte(nil), raw[8:]...)
+		ok = true
+		return nil
+	})
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return value, ok, err
+}
+
+// Set writes value through to disk under groupName/key, stamped to expire
+// after the configured TTL.
+func (c *diskCache) Set(groupName, key string, value []byte) error {
+	expiresAt := time.Now().Add(c.ttl).Unix()
+	raw := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(raw[:8], uint64(expiresAt))
+	copy(raw[8:], value)
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(groupName))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), raw)
+	})
+	if err == nil {
+		atomic.AddUint64(&c.writes, 1)
+	}
+	return err
+}
+
+// legacyJSONEntry mirrors the shape of the file-based JSON cache entries
+// that predate the BoltDB tier.
+type legacyJSONEntry struct {
+	GroupName string          `json:"group_name"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// importJSONCache writes already-decoded legacy JSON cache entries (see
+// importJSONCacheFile for reading them from disk) into the BoltDB store, so
+// operators upgrading from the old on-disk format don't lose their warm
+// cache.
+func (c *diskCache) importJSONCache(entries []legacyJSONEntry) (imported int, err error) {
+	for _, e := range entries {
+		if err := c.Set(e.GroupName, e.Key, e.Value); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, nil
+}
+
+func (c *diskCache) Close() error {
+	return c.db.Close()
+}
+
+// importJSONCacheFile reads a newline-delimited-JSON file of legacyJSONEntry
+// records and imports it into dc.
+func importJSONCacheFile(dc *diskCache, path string) (imported int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var entries []legacyJSONEntry
+	dec := json.NewDecoder(f)
+	for {
+		var e legacyJSONEntry
+		if err := dec.Decode(&e); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+	return dc.importJSONCache(entries)
+}
+
+type cacheTierStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Writes uint64 `json:"writes"`
+}
+
+// cacheStatsHandler reports hit/miss/write counts for the disk tier so
+// operators can tell whether the persistent cache is actually absorbing
+// restarts.
+func (c *diskCache) cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := cacheTierStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Writes: atomic.LoadUint64(&c.writes),
+	}
+	blob, err := json.Marshal(stats)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheGetSetRoundTrip(t *testing.T) {
+	dc, err := newDiskCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour, "address_lookup")
+	if err != nil {
+		t.Fatalf("newDiskCache: %v", err)
+	}
+	defer dc.Close()
+
+	if _, ok, err := dc.Get("address_lookup", "palo alto"); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v on empty cache, want a clean miss", ok, err)
+	}
+
+	if err := dc.Set("address_lookup", "palo alto", []byte(`{"features":[]}`)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	value, ok, err := dc.Get("address_lookup", "palo alto")
+	if err != nil || !ok {
+		t.Fatalf("got ok=%v err=%v, want a hit", ok, err)
+	}
+	if string(value) != `{"features":[]}` {
+		t.Fatalf("got value %q, want the round-tripped blob", value)
+	}
+}
+
+func TestDiskCacheExpiry(t *testing.T) {
+	dc, err := newDiskCache(filepath.Join(t.TempDir(), "cache.db"), -time.Minute, "address_lookup")
+	if err != nil {
+		t.Fatalf("newDiskCache: %v", err)
+	}
+	defer dc.Close()
+
+	// A negative TTL stamps every entry as already expired, exercising the
+	// expiresAt check in Get without needing to sleep in the test.
+	if err := dc.Set("address_lookup", "palo alto", []byte(`{}`)); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, ok, err := dc.Get("address_lookup", "palo alto"); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v, want an expired entry to report as a miss", ok, err)
+	}
+}
+
+func TestImportJSONCache(t *testing.T) {
+	dc, err := newDiskCache(filepath.Join(t.TempDir(), "cache.db"), time.Hour, "address_lookup")
+	if err != nil {
+		t.Fatalf("newDiskCache: %v", err)
+	}
+	defer dc.Close()
+
+	entries := []legacyJSONEntry{
+		{GroupName: "address_lookup", Key: "palo alto", Value: []byte(`{"a":1}`)},
+		{GroupName: "address_lookup", Key: "reno", Value: []byte(`{"a":2}`)},
+	}
+	n, err := dc.importJSONCache(entries)
+	if err != nil {
+		t.Fatalf("importJSONCache: %v", err)
+	}
+	if n != len(entries) {
+		t.Fatalf("got %d imported, want %d", n, len(entries))
+	}
+
+	value, ok, err := dc.Get("address_lookup", "reno")
+	if err != nil || !ok {
+		t.Fatalf("got ok=%v err=%v, want the imported entry to be readable", ok, err)
+	}
+	if string(value) != `{"a":2}` {
+		t.Fatalf("got value %q, want the imported blob", value)
+	}
+}
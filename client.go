@@ -11,6 +11,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/orijtech/mapbox"
@@ -26,12 +27,16 @@ var httpClient = &http.Client{Transport: &ochttp.Transport{}}
 
 func main() {
 	serverAddr := flag.String("server_url", "http://localhost:8777", "the server of the mapbox search cluster")
+	transport := flag.String("transport", "http", "which transport to talk to the server over: http or grpc")
 	flag.Parse()
 
 	if err := enableOpenCensus(); err != nil {
 		log.Fatalf("Failed to enable OpenCensus: %v", err)
 	}
-	mc := &client{addr: *serverAddr}
+	mc, err := newSearchClient(*transport, *serverAddr)
+	if err != nil {
+		log.Fatalf("Failed to create a %s client: %v", *transport, err)
+	}
 
 	br := bufio.NewReader(os.Stdin)
 	for {
@@ -48,10 +53,33 @@ func main() {
 	}
 }
 
+// searchClient is implemented by both the JSON-over-HTTP client and the
+// gRPC client, so the CLI's read-eval loop doesn't care which transport
+// -transport selected.
+type searchClient interface {
+	LookupByName(ctx context.Context, name string) (*mapbox.GeocodeResponse, error)
+	LookupByLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error)
+}
+
+// newSearchClient builds the searchClient named by transport ("http" or
+// "grpc") against serverAddr.
+func newSearchClient(transport, serverAddr string) (searchClient, error) {
+	switch transport {
+	case "", "http":
+		return &client{addr: serverAddr}, nil
+	case "grpc":
+		return newGRPCClient(strings.TrimPrefix(strings.TrimPrefix(serverAddr, "https://"), "http://"))
+	default:
+		return nil, fmt.Errorf("unrecognized transport %q", transport)
+	}
+}
+
 type client struct {
 	addr string
 }
 
+var _ searchClient = (*client)(nil)
+
 type query struct {
 	Name string  `json:"name"`
 	Lat  float64 `json:"lat"`
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// peerDiscoverer reconciles the groupcache peer set against some external
+// source of truth. watch should block, invoking onChange with the full
+// current peer list every time membership changes, until ctx is canceled.
+type peerDiscoverer interface {
+	watch(ctx context.Context, onChange func(peers []string)) error
+}
+
+// newDiscoverer builds the peerDiscoverer named by kind ("static", "consul"
+// or "k8s"). staticPeers is used verbatim by the static discoverer; consul
+// and k8s discoverers instead watch serviceName for membership changes.
+// namespace is only consulted in k8s mode; an empty value means "the pod's
+// own namespace".
+func newDiscoverer(kind string, staticPeers []string, serviceName, namespace string) (peerDiscoverer, error) {
+	switch kind {
+	case "", "static":
+		return &staticDiscoverer{peers: staticPeers}, nil
+	case "consul":
+		return newConsulDiscoverer(serviceName)
+	case "k8s":
+		return newK8sDiscoverer(serviceName, namespace)
+	default:
+		return nil, fmt.Errorf("discovery: unrecognized mode %q", kind)
+	}
+}
+
+// staticDiscoverer just reports the peers it was started with once; this is
+// the default for local dev where there is no discovery backend to talk to.
+type staticDiscoverer struct {
+	peers []string
+}
+
+func (d *staticDiscoverer) watch(ctx context.Context, onChange func(peers []string)) error {
+	onChange(d.peers)
+	<-ctx.Done()
+	return nil
+}
+
+// consulDiscoverer watches a Consul service's healthy instances, in the
+// style of jo-micro/geoip's service registration pattern.
+type consulDiscoverer struct {
+	client      *consulapi.Client
+	serviceName string
+}
+
+func newConsulDiscoverer(serviceName string) (*consulDiscoverer, error) {
+	client, err := consulapi.NewClient(consulapi.DefaultConfig())
+	if err != nil {
+		return nil, fmt.Errorf("discovery: failed to create consul client: %v", err)
+	}
+	return &consulDiscoverer{client: client, serviceName: serviceName}, nil
+}
+
+func (d *consulDiscoverer) watch(ctx context.Context, onChange func(peers []string)) error {
+	debounced := debounce(onChange, 2*time.Second)
+	var lastIndex uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		entries, meta, err := d.client.Health().Service(d.serviceName, "", true, &consulapi.QueryOptions{
+			WaitIndex: lastIndex,
+			WaitTime:  5 * time.Minute,
+		})
+		if err != nil {
+			log.Printf("discovery: consul watch error: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		lastIndex = meta.LastIndex
+
+		peers := make([]string, 0, len(entries))
+		for _, e := range entries {
+			peers = append(peers, fmt.Sprintf("http://%s:%d", e.Service.Address, e.Service.Port))
+		}
+		debounced(peers)
+	}
+}
+
+// k8sDiscoverer watches a Kubernetes Service's Endpoints for membership
+// changes, reconciling pool.Set(...) whenever pods are added or removed.
+type k8sDiscoverer struct {
+	clientset   *kubernetes.Clientset
+	namespace   string
+	serviceName string
+}
+
+// serviceAccountNamespaceFile is where Kubernetes projects a pod's own
+// namespace via the downward API; every in-cluster pod can read it.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+func newK8sDiscoverer(serviceName, namespace string) (*k8sDiscoverer, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("discovery: failed to load in-cluster config: %v", err)
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: failed to create k8s clientset: %v", err)
+	}
+	if namespace == "" {
+		namespace = ownNamespaceOrDefault()
+	}
+	return &k8sDiscoverer{clientset: clientset, namespace: namespace, serviceName: serviceName}, nil
+}
+
+// ownNamespaceOrDefault reads the pod's own namespace from the downward
+// API, falling back to "default" when it can't be read (e.g. running
+// outside a cluster).
+func ownNamespaceOrDefault() string {
+	blob, err := ioutil.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return "default"
+	}
+	return strings.TrimSpace(string(blob))
+}
+
+func (d *k8sDiscoverer) watch(ctx context.Context, onChange func(peers []string)) error {
+	debounced := debounce(onChange, 2*time.Second)
+
+	// The API server closes watches periodically, so re-establish the watch
+	// each time its channel closes until ctx is canceled.
+	for ctx.Err() == nil {
+		watcher, err := d.clientset.CoreV1().Endpoints(d.namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: "metadata.name=" + d.serviceName,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("discovery: failed to watch endpoints: %v", err)
+		}
+		for event := range watcher.ResultChan() {
+			ep, ok := event.Object.(*corev1.Endpoints)
+			if !ok {
+				continue
+			}
+			debounced(peersFromEndpoints(ep))
+		}
+		watcher.Stop()
+	}
+	return nil
+}
+
+func peersFromEndpoints(ep *corev1.Endpoints) []string {
+	var peers []string
+	for _, subset := range ep.Subsets {
+		for _, addr := range subset.Addresses {
+			for _, port := range subset.Ports {
+				peers = append(peers, fmt.Sprintf("http://%s:%d", addr.IP, port.Port))
+			}
+		}
+	}
+	return peers
+}
+
+// debounce wraps onChange so that bursts of calls within window collapse
+// into a single call carrying the most recent peer list.
+func debounce(onChange func(peers []string), window time.Duration) func(peers []string) {
+	var mu sync.Mutex
+	var timer *time.Timer
+	return func(peers []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(window, func() { onChange(peers) })
+	}
+}
+
+// reconcilePeers applies a freshly discovered peer list to the groupcache
+// pool and records a churn event.
+func reconcilePeers(peers []string) {
+	poolMu.Lock()
+	pool.Set(peers...)
+	poolMu.Unlock()
+
+	stats.Record(context.Background(), mPeerChurn.M(1))
+}
+
+var mPeerChurn = stats.Int64("mapboxsearch/peer_churn", "Number of peer-set reconciliations triggered by discovery", stats.UnitDimensionless)
+
+var peerChurnView = &view.View{
+	Name:        "mapboxsearch/peer_churn",
+	Measure:     mPeerChurn,
+	Description: "Count of peer-set reconciliations triggered by discovery",
+	Aggregation: view.Count(),
+}
@@ -0,0 +1,169 @@
+// Hand-written message types for geocode.proto. protoc isn't vendored in
+// this tree, so these are maintained by hand instead of generated; keep
+// them in sync with geocode.proto when the schema changes.
+
+package geocodepb
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+type LookupByNameRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (m *LookupByNameRequest) Reset()         { *m = LookupByNameRequest{} }
+func (m *LookupByNameRequest) String() string { return proto.CompactTextString(m) }
+func (*LookupByNameRequest) ProtoMessage()    {}
+
+func (m *LookupByNameRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type LookupByLatLonRequest struct {
+	Lat float64 `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon float64 `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+}
+
+func (m *LookupByLatLonRequest) Reset()         { *m = LookupByLatLonRequest{} }
+func (m *LookupByLatLonRequest) String() string { return proto.CompactTextString(m) }
+func (*LookupByLatLonRequest) ProtoMessage()    {}
+
+func (m *LookupByLatLonRequest) GetLat() float64 {
+	if m != nil {
+		return m.Lat
+	}
+	return 0
+}
+
+func (m *LookupByLatLonRequest) GetLon() float64 {
+	if m != nil {
+		return m.Lon
+	}
+	return 0
+}
+
+type SetPeersRequest struct {
+	GroupName string   `protobuf:"bytes,1,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	Peers     []string `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+}
+
+func (m *SetPeersRequest) Reset()         { *m = SetPeersRequest{} }
+func (m *SetPeersRequest) String() string { return proto.CompactTextString(m) }
+func (*SetPeersRequest) ProtoMessage()    {}
+
+func (m *SetPeersRequest) GetGroupName() string {
+	if m != nil {
+		return m.GroupName
+	}
+	return ""
+}
+
+func (m *SetPeersRequest) GetPeers() []string {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+type SetPeersResponse struct{}
+
+func (m *SetPeersResponse) Reset()         { *m = SetPeersResponse{} }
+func (m *SetPeersResponse) String() string { return proto.CompactTextString(m) }
+func (*SetPeersResponse) ProtoMessage()    {}
+
+type BatchLookupRequest struct {
+	ByName   []*LookupByNameRequest   `protobuf:"bytes,1,rep,name=by_name,json=byName,proto3" json:"by_name,omitempty"`
+	ByLatLon []*LookupByLatLonRequest `protobuf:"bytes,2,rep,name=by_lat_lon,json=byLatLon,proto3" json:"by_lat_lon,omitempty"`
+}
+
+func (m *BatchLookupRequest) Reset()         { *m = BatchLookupRequest{} }
+func (m *BatchLookupRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchLookupRequest) ProtoMessage()    {}
+
+func (m *BatchLookupRequest) GetByName() []*LookupByNameRequest {
+	if m != nil {
+		return m.ByName
+	}
+	return nil
+}
+
+func (m *BatchLookupRequest) GetByLatLon() []*LookupByLatLonRequest {
+	if m != nil {
+		return m.ByLatLon
+	}
+	return nil
+}
+
+type Feature struct {
+	PlaceName string    `protobuf:"bytes,1,opt,name=place_name,json=placeName,proto3" json:"place_name,omitempty"`
+	Relevance float64   `protobuf:"fixed64,2,opt,name=relevance,proto3" json:"relevance,omitempty"`
+	Center    []float64 `protobuf:"fixed64,3,rep,packed,name=center,proto3" json:"center,omitempty"`
+}
+
+func (m *Feature) Reset()         { *m = Feature{} }
+func (m *Feature) String() string { return proto.CompactTextString(m) }
+func (*Feature) ProtoMessage()    {}
+
+type GeocodeResponse struct {
+	Features []*Feature `protobuf:"bytes,1,rep,name=features,proto3" json:"features,omitempty"`
+}
+
+func (m *GeocodeResponse) Reset()         { *m = GeocodeResponse{} }
+func (m *GeocodeResponse) String() string { return proto.CompactTextString(m) }
+func (*GeocodeResponse) ProtoMessage()    {}
+
+func (m *GeocodeResponse) GetFeatures() []*Feature {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+// BatchLookupResponse carries the result for one slot of a BatchLookup
+// request; Index ties it back to its position in the request so a failed
+// slot doesn't need to abort the rest of the stream.
+type BatchLookupResponse struct {
+	Index  int32            `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Result *GeocodeResponse `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error  string           `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *BatchLookupResponse) Reset()         { *m = BatchLookupResponse{} }
+func (m *BatchLookupResponse) String() string { return proto.CompactTextString(m) }
+func (*BatchLookupResponse) ProtoMessage()    {}
+
+func (m *BatchLookupResponse) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *BatchLookupResponse) GetResult() *GeocodeResponse {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *BatchLookupResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*LookupByNameRequest)(nil), "geocodepb.LookupByNameRequest")
+	proto.RegisterType((*LookupByLatLonRequest)(nil), "geocodepb.LookupByLatLonRequest")
+	proto.RegisterType((*SetPeersRequest)(nil), "geocodepb.SetPeersRequest")
+	proto.RegisterType((*SetPeersResponse)(nil), "geocodepb.SetPeersResponse")
+	proto.RegisterType((*BatchLookupRequest)(nil), "geocodepb.BatchLookupRequest")
+	proto.RegisterType((*Feature)(nil), "geocodepb.Feature")
+	proto.RegisterType((*GeocodeResponse)(nil), "geocodepb.GeocodeResponse")
+	proto.RegisterType((*BatchLookupResponse)(nil), "geocodepb.BatchLookupResponse")
+}
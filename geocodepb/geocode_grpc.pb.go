@@ -0,0 +1,204 @@
+// Hand-written gRPC client/server stubs for geocode.proto, mirroring what
+// protoc-gen-go-grpc would emit. protoc isn't vendored in this tree, so
+// maintain these by hand in sync with geocode.proto until it is.
+
+package geocodepb
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// GeocodeServiceClient is the client API for GeocodeService.
+type GeocodeServiceClient interface {
+	LookupByName(ctx context.Context, in *LookupByNameRequest, opts ...grpc.CallOption) (*GeocodeResponse, error)
+	LookupByLatLon(ctx context.Context, in *LookupByLatLonRequest, opts ...grpc.CallOption) (*GeocodeResponse, error)
+	SetPeers(ctx context.Context, in *SetPeersRequest, opts ...grpc.CallOption) (*SetPeersResponse, error)
+	BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (GeocodeService_BatchLookupClient, error)
+}
+
+type geocodeServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGeocodeServiceClient(cc *grpc.ClientConn) GeocodeServiceClient {
+	return &geocodeServiceClient{cc}
+}
+
+func (c *geocodeServiceClient) LookupByName(ctx context.Context, in *LookupByNameRequest, opts ...grpc.CallOption) (*GeocodeResponse, error) {
+	out := new(GeocodeResponse)
+	err := c.cc.Invoke(ctx, "/geocodepb.GeocodeService/LookupByName", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geocodeServiceClient) LookupByLatLon(ctx context.Context, in *LookupByLatLonRequest, opts ...grpc.CallOption) (*GeocodeResponse, error) {
+	out := new(GeocodeResponse)
+	err := c.cc.Invoke(ctx, "/geocodepb.GeocodeService/LookupByLatLon", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geocodeServiceClient) SetPeers(ctx context.Context, in *SetPeersRequest, opts ...grpc.CallOption) (*SetPeersResponse, error) {
+	out := new(SetPeersResponse)
+	err := c.cc.Invoke(ctx, "/geocodepb.GeocodeService/SetPeers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *geocodeServiceClient) BatchLookup(ctx context.Context, in *BatchLookupRequest, opts ...grpc.CallOption) (GeocodeService_BatchLookupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_GeocodeService_serviceDesc.Streams[0], "/geocodepb.GeocodeService/BatchLookup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &geocodeServiceBatchLookupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GeocodeService_BatchLookupClient interface {
+	Recv() (*BatchLookupResponse, error)
+	grpc.ClientStream
+}
+
+type geocodeServiceBatchLookupClient struct {
+	grpc.ClientStream
+}
+
+func (x *geocodeServiceBatchLookupClient) Recv() (*BatchLookupResponse, error) {
+	m := new(BatchLookupResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GeocodeServiceServer is the server API for GeocodeService.
+type GeocodeServiceServer interface {
+	LookupByName(context.Context, *LookupByNameRequest) (*GeocodeResponse, error)
+	LookupByLatLon(context.Context, *LookupByLatLonRequest) (*GeocodeResponse, error)
+	SetPeers(context.Context, *SetPeersRequest) (*SetPeersResponse, error)
+	BatchLookup(*BatchLookupRequest, GeocodeService_BatchLookupServer) error
+}
+
+// UnimplementedGeocodeServiceServer can be embedded to have forward
+// compatible implementations.
+type UnimplementedGeocodeServiceServer struct{}
+
+func (*UnimplementedGeocodeServiceServer) LookupByName(context.Context, *LookupByNameRequest) (*GeocodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupByName not implemented")
+}
+
+func (*UnimplementedGeocodeServiceServer) LookupByLatLon(context.Context, *LookupByLatLonRequest) (*GeocodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupByLatLon not implemented")
+}
+
+func (*UnimplementedGeocodeServiceServer) SetPeers(context.Context, *SetPeersRequest) (*SetPeersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeers not implemented")
+}
+
+func (*UnimplementedGeocodeServiceServer) BatchLookup(*BatchLookupRequest, GeocodeService_BatchLookupServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+
+func RegisterGeocodeServiceServer(s *grpc.Server, srv GeocodeServiceServer) {
+	s.RegisterService(&_GeocodeService_serviceDesc, srv)
+}
+
+func _GeocodeService_LookupByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeocodeServiceServer).LookupByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/geocodepb.GeocodeService/LookupByName"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeocodeServiceServer).LookupByName(ctx, req.(*LookupByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeocodeService_LookupByLatLon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupByLatLonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeocodeServiceServer).LookupByLatLon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/geocodepb.GeocodeService/LookupByLatLon"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeocodeServiceServer).LookupByLatLon(ctx, req.(*LookupByLatLonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeocodeService_SetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GeocodeServiceServer).SetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/geocodepb.GeocodeService/SetPeers"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GeocodeServiceServer).SetPeers(ctx, req.(*SetPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GeocodeService_BatchLookup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchLookupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GeocodeServiceServer).BatchLookup(m, &geocodeServiceBatchLookupServer{stream})
+}
+
+type GeocodeService_BatchLookupServer interface {
+	Send(*BatchLookupResponse) error
+	grpc.ServerStream
+}
+
+type geocodeServiceBatchLookupServer struct {
+	grpc.ServerStream
+}
+
+func (x *geocodeServiceBatchLookupServer) Send(m *BatchLookupResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _GeocodeService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "geocodepb.GeocodeService",
+	HandlerType: (*GeocodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "LookupByName", Handler: _GeocodeService_LookupByName_Handler},
+		{MethodName: "LookupByLatLon", Handler: _GeocodeService_LookupByLatLon_Handler},
+		{MethodName: "SetPeers", Handler: _GeocodeService_SetPeers_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchLookup",
+			Handler:       _GeocodeService_BatchLookup_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "geocode.proto",
+}
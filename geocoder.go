@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/orijtech/mapbox"
+)
+
+// Geocoder is implemented by every geocoding backend this server knows how
+// to talk to. Implementations normalize their own response shape into
+// mapbox.GeocodeResponse.
+type Geocoder interface {
+	// Name identifies the backend, used in logs and the -backends flag.
+	Name() string
+
+	// LookupPlace resolves a free-form place name e.g. "Palo Alto".
+	LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error)
+
+	// LookupLatLon resolves a latitude/longitude pair to a place.
+	LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error)
+}
+
+// ipGeocoder is implemented by backends that resolve IP addresses rather
+// than free-form place names, e.g. maxMindGeocoder. It is deliberately not
+// part of Geocoder: a backend that only understands IPs doesn't satisfy
+// LookupPlace's "free-form place name" contract, so chainGeocoder type-
+// asserts for this instead of routing IP-shaped queries through
+// LookupPlace.
+type ipGeocoder interface {
+	LookupIP(ctx context.Context, ip string) (*mapbox.GeocodeResponse, error)
+}
+
+// chainGeocoder tries each Geocoder in order, falling through to the next
+// one when a backend errors out or returns no features. It is itself a
+// Geocoder so it can be used anywhere a single backend is expected.
+type chainGeocoder struct {
+	backends []Geocoder
+}
+
+var _ Geocoder = (*chainGeocoder)(nil)
+
+// lookupPlaceOrIP routes placeName through b's LookupIP when b is an
+// ipGeocoder and placeName parses as an IP address, and through its
+// LookupPlace otherwise.
+func lookupPlaceOrIP(ctx context.Context, b Geocoder, placeName string) (*mapbox.GeocodeResponse, error) {
+	if ipb, ok := b.(ipGeocoder); ok {
+		if net.ParseIP(placeName) != nil {
+			return ipb.LookupIP(ctx, placeName)
+		}
+	}
+	return b.LookupPlace(ctx, placeName)
+}
+
+func (c *chainGeocoder) Name() string {
+	names := make([]string, len(c.backends))
+	for i, b := range c.backends {
+		names[i] = b.Name()
+	}
+	return strings.Join(names, "->")
+}
+
+func (c *chainGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	var lastErr error
+	for _, b := range c.backends {
+		res, err := lookupPlaceOrIP(ctx, b, placeName)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", b.Name(), err)
+			continue
+		}
+		if len(res.Features) == 0 {
+			continue
+		}
+		return res, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no backend returned any features for %q", placeName)
+	}
+	return nil, lastErr
+}
+
+func (c *chainGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	var lastErr error
+	for _, b := range c.backends {
+		res, err := b.LookupLatLon(ctx, lat, lon)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", b.Name(), err)
+			continue
+		}
+		if len(res.Features) == 0 {
+			continue
+		}
+		return res, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no backend returned any features for %f,%f", lat, lon)
+	}
+	return nil, lastErr
+}
+
+// mapboxGeocoder adapts the existing *mapbox.Client to the Geocoder
+// interface. Since mapbox.GeocodeResponse is already our normalized shape,
+// no translation is necessary here.
+type mapboxGeocoder struct {
+	client *mapbox.Client
+}
+
+var _ Geocoder = (*mapboxGeocoder)(nil)
+
+// newMapboxGeocoder creates the mapbox.Client on demand, so a server run
+// with e.g. "-backends nominatim" never needs Mapbox credentials at all.
+func newMapboxGeocoder() (*mapboxGeocoder, error) {
+	client, err := mapbox.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("mapbox: %v", err)
+	}
+	return &mapboxGeocoder{client: client}, nil
+}
+
+func (g *mapboxGeocoder) Name() string { return "mapbox" }
+
+func (g *mapboxGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	return g.client.LookupPlace(ctx, placeName)
+}
+
+func (g *mapboxGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	return g.client.LookupLatLon(ctx, lat, lon)
+}
+
+// newGeocoderChain builds a chainGeocoder out of the comma separated
+// "-backends" flag value, e.g. "mapbox,nominatim,maxmind,google". Backends
+// are tried in the order given. When only one backend is named, that
+// backend is returned as is, without a chainGeocoder around it.
+func newGeocoderChain(backendsCSV string) (Geocoder, error) {
+	names := strings.Split(backendsCSV, ",")
+	chain := new(chainGeocoder)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		backend, err := newGeocoderByName(name)
+		if err != nil {
+			return nil, err
+		}
+		chain.backends = append(chain.backends, backend)
+	}
+	if len(chain.backends) == 0 {
+		return nil, fmt.Errorf("no usable backends in %q", backendsCSV)
+	}
+	if len(chain.backends) == 1 {
+		return chain.backends[0], nil
+	}
+	return chain, nil
+}
+
+func newGeocoderByName(name string) (Geocoder, error) {
+	switch name {
+	case "mapbox":
+		return newMapboxGeocoder()
+	case "nominatim", "osm", "openstreetmap":
+		return newNominatimGeocoder()
+	case "maxmind", "geoip2":
+		return newMaxMindGeocoder()
+	case "google":
+		return newGoogleGeocoder()
+	default:
+		return nil, fmt.Errorf("unrecognized geocoding backend %q", name)
+	}
+}
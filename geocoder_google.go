@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/otils"
+)
+
+// googleGeocoder talks to the Google Geocoding API and normalizes its
+// responses into mapbox.GeocodeResponse.
+type googleGeocoder struct {
+	apiKey string
+}
+
+var _ Geocoder = (*googleGeocoder)(nil)
+
+func newGoogleGeocoder() (*googleGeocoder, error) {
+	apiKey := otils.EnvOrAlternates("GOOGLE_GEOCODING_API_KEY", "")
+	if apiKey == "" {
+		return nil, fmt.Errorf("google: missing GOOGLE_GEOCODING_API_KEY")
+	}
+	return &googleGeocoder{apiKey: apiKey}, nil
+}
+
+func (g *googleGeocoder) Name() string { return "google" }
+
+type googleGeocodeResponse struct {
+	Status  string `json:"status"`
+	Results []struct {
+		FormattedAddress string `json:"formatted_address"`
+		Geometry         struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+func (g *googleGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	q := url.Values{}
+	q.Set("address", placeName)
+	return g.do(ctx, q)
+}
+
+func (g *googleGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	q := url.Values{}
+	q.Set("latlng", fmt.Sprintf("%s,%s", strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64)))
+	return g.do(ctx, q)
+}
+
+func (g *googleGeocoder) do(ctx context.Context, q url.Values) (*mapbox.GeocodeResponse, error) {
+	q.Set("key", g.apiKey)
+	reqURL := fmt.Sprintf("https://maps.googleapis.com/maps/api/geocode/json?%s", q.Encode())
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if !otils.StatusOK(res.StatusCode) {
+		return nil, fmt.Errorf("google: %s", res.Status)
+	}
+
+	gres := new(googleGeocodeResponse)
+	if err := json.NewDecoder(res.Body).Decode(gres); err != nil {
+		return nil, err
+	}
+	if gres.Status != "OK" && gres.Status != "ZERO_RESULTS" {
+		return nil, fmt.Errorf("google: %s", gres.Status)
+	}
+
+	out := new(mapbox.GeocodeResponse)
+	for _, r := range gres.Results {
+		out.Features = append(out.Features, &mapbox.Feature{
+			PlaceName: r.FormattedAddress,
+			Relevance: 1.0,
+			Center:    []float64{r.Geometry.Location.Lng, r.Geometry.Location.Lat},
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/otils"
+	geoip2 "github.com/oschwald/geoip2-golang"
+)
+
+// maxMindGeocoder serves IP-based lookups out of a local GeoLite2/GeoIP2
+// City database. It has no notion of a free-form place name or of
+// reversing a lat/lon pair back to an IP, so LookupPlace always fails and
+// LookupLatLon is unsupported; IP lookups are served through LookupIP
+// instead, which chainGeocoder reaches via a type assertion to ipGeocoder.
+type maxMindGeocoder struct {
+	db *geoip2.Reader
+}
+
+var _ Geocoder = (*maxMindGeocoder)(nil)
+var _ ipGeocoder = (*maxMindGeocoder)(nil)
+
+func newMaxMindGeocoder() (*maxMindGeocoder, error) {
+	dbPath := otils.EnvOrAlternates("MAXMIND_GEOIP2_DB_PATH", "GeoLite2-City.mmdb")
+	db, err := geoip2.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("maxmind: failed to open %q: %v", dbPath, err)
+	}
+	return &maxMindGeocoder{db: db}, nil
+}
+
+func (g *maxMindGeocoder) Name() string { return "maxmind" }
+
+func (g *maxMindGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	return nil, fmt.Errorf("maxmind: place-name lookup is not supported, this backend is IP-only; use LookupIP")
+}
+
+func (g *maxMindGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	return nil, fmt.Errorf("maxmind: lat/lon lookup is not supported, this backend is IP-only")
+}
+
+// LookupIP resolves ip, a dotted-quad or IPv6 address, via the local
+// GeoLite2/GeoIP2 City database.
+func (g *maxMindGeocoder) LookupIP(ctx context.Context, ip string) (*mapbox.GeocodeResponse, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("maxmind: %q is not an IP address", ip)
+	}
+	record, err := g.db.City(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeMaxMindRecord(record), nil
+}
+
+func normalizeMaxMindRecord(record *geoip2.City) *mapbox.GeocodeResponse {
+	name := record.City.Names["en"]
+	if len(record.Subdivisions) > 0 {
+		name = fmt.Sprintf("%s, %s", name, record.Subdivisions[0].Names["en"])
+	}
+	if record.Country.Names["en"] != "" {
+		name = fmt.Sprintf("%s, %s", name, record.Country.Names["en"])
+	}
+	return &mapbox.GeocodeResponse{
+		Features: []*mapbox.Feature{
+			{
+				PlaceName: name,
+				Relevance: 1.0,
+				Center:    []float64{record.Location.Longitude, record.Location.Latitude},
+			},
+		},
+	}
+}
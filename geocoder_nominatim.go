@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/otils"
+)
+
+// nominatimGeocoder talks to the OpenStreetMap Nominatim public API and
+// normalizes its responses into mapbox.GeocodeResponse so that it can be
+// chained transparently with the other backends.
+type nominatimGeocoder struct {
+	baseURL string
+}
+
+var _ Geocoder = (*nominatimGeocoder)(nil)
+
+func newNominatimGeocoder() (*nominatimGeocoder, error) {
+	baseURL := otils.EnvOrAlternates("NOMINATIM_BASE_URL", "https://nominatim.openstreetmap.org")
+	return &nominatimGeocoder{baseURL: baseURL}, nil
+}
+
+func (g *nominatimGeocoder) Name() string { return "nominatim" }
+
+type nominatimResult struct {
+	DisplayName string  `json:"display_name"`
+	Lat         string  `json:"lat"`
+	Lon         string  `json:"lon"`
+	Importance  float32 `json:"importance"`
+}
+
+func (g *nominatimGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	q := url.Values{}
+	q.Set("q", placeName)
+	q.Set("format", "json")
+	return g.search(ctx, q)
+}
+
+func (g *nominatimGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	q := url.Values{}
+	q.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+	q.Set("format", "json")
+	return g.reverse(ctx, q)
+}
+
+func (g *nominatimGeocoder) search(ctx context.Context, q url.Values) (*mapbox.GeocodeResponse, error) {
+	results, err := g.do(ctx, "/search", q)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeNominatimResults(results)
+}
+
+func (g *nominatimGeocoder) reverse(ctx context.Context, q url.Values) (*mapbox.GeocodeResponse, error) {
+	results, err := g.do(ctx, "/reverse", q)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeNominatimResults(results)
+}
+
+func (g *nominatimGeocoder) do(ctx context.Context, path string, q url.Values) ([]*nominatimResult, error) {
+	reqURL := fmt.Sprintf("%s%s?%s", g.baseURL, path, q.Encode())
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", "mapbox-search/1.0")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if !otils.StatusOK(res.StatusCode) {
+		return nil, fmt.Errorf("nominatim: %s", res.Status)
+	}
+
+	var results []*nominatimResult
+	// The /reverse endpoint returns a single object rather than a list.
+	dec := json.NewDecoder(res.Body)
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &results); err != nil {
+		single := new(nominatimResult)
+		if err := json.Unmarshal(raw, single); err != nil {
+			return nil, err
+		}
+		results = []*nominatimResult{single}
+	}
+	return results, nil
+}
+
+func normalizeNominatimResults(results []*nominatimResult) (*mapbox.GeocodeResponse, error) {
+	gres := new(mapbox.GeocodeResponse)
+	for _, r := range results {
+		lat, err := strconv.ParseFloat(r.Lat, 64)
+		if err != nil {
+			continue
+		}
+		lon, err := strconv.ParseFloat(r.Lon, 64)
+		if err != nil {
+			continue
+		}
+		gres.Features = append(gres.Features, &mapbox.Feature{
+			PlaceName: r.DisplayName,
+			Relevance: float64(r.Importance),
+			Center:    []float64{lon, lat},
+		})
+	}
+	return gres, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/orijtech/mapbox"
+)
+
+// fakeIPGeocoder is a minimal Geocoder that also implements ipGeocoder, to
+// exercise chainGeocoder's routing between LookupPlace and LookupIP without
+// needing a real MaxMind database.
+type fakeIPGeocoder struct {
+	gotPlace, gotIP string
+}
+
+func (g *fakeIPGeocoder) Name() string { return "fake-ip" }
+
+func (g *fakeIPGeocoder) LookupPlace(ctx context.Context, placeName string) (*mapbox.GeocodeResponse, error) {
+	g.gotPlace = placeName
+	return nil, errLookupPlaceCalled
+}
+
+func (g *fakeIPGeocoder) LookupLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	return nil, errLookupPlaceCalled
+}
+
+func (g *fakeIPGeocoder) LookupIP(ctx context.Context, ip string) (*mapbox.GeocodeResponse, error) {
+	g.gotIP = ip
+	return &mapbox.GeocodeResponse{Features: []*mapbox.Feature{{PlaceName: "resolved:" + ip}}}, nil
+}
+
+var errLookupPlaceCalled = errors.New("LookupPlace/LookupLatLon should not have been called")
+
+func TestLookupPlaceOrIPRoutesIPsToLookupIP(t *testing.T) {
+	g := &fakeIPGeocoder{}
+
+	res, err := lookupPlaceOrIP(context.Background(), g, "8.8.8.8")
+	if err != nil {
+		t.Fatalf("lookupPlaceOrIP: %v", err)
+	}
+	if g.gotIP != "8.8.8.8" || g.gotPlace != "" {
+		t.Fatalf("got gotIP=%q gotPlace=%q, want LookupIP called with the IP and LookupPlace left untouched", g.gotIP, g.gotPlace)
+	}
+	if len(res.Features) != 1 || res.Features[0].PlaceName != "resolved:8.8.8.8" {
+		t.Fatalf("got %+v, want a single resolved feature", res)
+	}
+}
+
+func TestLookupPlaceOrIPRoutesNonIPsToLookupPlace(t *testing.T) {
+	g := &fakeIPGeocoder{}
+
+	_, err := lookupPlaceOrIP(context.Background(), g, "Palo Alto")
+	if err != errLookupPlaceCalled {
+		t.Fatalf("got err=%v, want LookupPlace to have been called", err)
+	}
+	if g.gotPlace != "Palo Alto" || g.gotIP != "" {
+		t.Fatalf("got gotPlace=%q gotIP=%q, want LookupPlace called with the place name and LookupIP left untouched", g.gotPlace, g.gotIP)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/mapbox-search/geocodepb"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+)
+
+// grpcClient mirrors client but talks to a server's GeocodeService over
+// gRPC instead of the JSON HTTP endpoints.
+type grpcClient struct {
+	conn *grpc.ClientConn
+	stub geocodepb.GeocodeServiceClient
+}
+
+var _ searchClient = (*grpcClient)(nil)
+
+// newGRPCClient dials serverAddr (host:port) and returns a ready-to-use
+// grpcClient. Callers are responsible for calling Close when done.
+func newGRPCClient(serverAddr string) (*grpcClient, error) {
+	conn, err := grpc.Dial(serverAddr, grpc.WithInsecure(), grpc.WithStatsHandler(&ocgrpc.ClientHandler{}))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClient{conn: conn, stub: geocodepb.NewGeocodeServiceClient(conn)}, nil
+}
+
+func (c *grpcClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *grpcClient) LookupByLatLon(ctx context.Context, lat, lon float64) (*mapbox.GeocodeResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "(*grpcClient).LookupByLatLon")
+	defer span.End()
+
+	gres, err := c.stub.LookupByLatLon(ctx, &geocodepb.LookupByLatLonRequest{Lat: lat, Lon: lon})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoGeocodeResponse(gres), nil
+}
+
+func (c *grpcClient) LookupByName(ctx context.Context, name string) (*mapbox.GeocodeResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "(*grpcClient).LookupByName")
+	defer span.End()
+
+	gres, err := c.stub.LookupByName(ctx, &geocodepb.LookupByNameRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return fromProtoGeocodeResponse(gres), nil
+}
+
+func fromProtoGeocodeResponse(gres *geocodepb.GeocodeResponse) *mapbox.GeocodeResponse {
+	out := new(mapbox.GeocodeResponse)
+	for _, feat := range gres.GetFeatures() {
+		out.Features = append(out.Features, &mapbox.Feature{
+			PlaceName: feat.GetPlaceName(),
+			Relevance: feat.GetRelevance(),
+			Center:    feat.GetCenter(),
+		})
+	}
+	return out
+}
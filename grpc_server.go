@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/golang/groupcache"
+	"github.com/orijtech/mapbox"
+	"github.com/orijtech/mapbox-search/geocodepb"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/stats/view"
+	"google.golang.org/grpc"
+)
+
+// geocodeServer implements geocodepb.GeocodeServiceServer on top of the same
+// groupcache-backed groups the JSON HTTP handlers use, so both API surfaces
+// share one cache and one set of peers.
+type geocodeServer struct {
+	geocodepb.UnimplementedGeocodeServiceServer
+}
+
+var _ geocodepb.GeocodeServiceServer = (*geocodeServer)(nil)
+
+func (s *geocodeServer) LookupByName(ctx context.Context, req *geocodepb.LookupByNameRequest) (*geocodepb.GeocodeResponse, error) {
+	return groupGetGeocode(ctx, byNameGroup, req.GetName())
+}
+
+func (s *geocodeServer) LookupByLatLon(ctx context.Context, req *geocodepb.LookupByLatLonRequest) (*geocodepb.GeocodeResponse, error) {
+	key := fmt.Sprintf("%.6f,%.6f", req.GetLat(), req.GetLon())
+	return groupGetGeocode(ctx, byLatLonGroup, key)
+}
+
+func (s *geocodeServer) SetPeers(ctx context.Context, req *geocodepb.SetPeersRequest) (*geocodepb.SetPeersResponse, error) {
+	poolMu.Lock()
+	pool.Set(req.GetPeers()...)
+	poolMu.Unlock()
+	return &geocodepb.SetPeersResponse{}, nil
+}
+
+func (s *geocodeServer) BatchLookup(req *geocodepb.BatchLookupRequest, stream geocodepb.GeocodeService_BatchLookupServer) error {
+	for _, resp := range batchLookupGRPC(stream.Context(), req) {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupGetGeocode issues a group.Get for key and unmarshals the cached JSON
+// blob into the protobuf GeocodeResponse shape.
+func groupGetGeocode(ctx context.Context, group *groupcache.Group, key string) (*geocodepb.GeocodeResponse, error) {
+	var data []byte
+	if err := group.Get(ctx, key, groupcache.AllocatingByteSliceSink(&data)); err != nil {
+		return nil, err
+	}
+	mres := new(mapbox.GeocodeResponse)
+	if err := json.Unmarshal(data, mres); err != nil {
+		return nil, err
+	}
+	return toProtoGeocodeResponse(mres), nil
+}
+
+func toProtoGeocodeResponse(mres *mapbox.GeocodeResponse) *geocodepb.GeocodeResponse {
+	out := &geocodepb.GeocodeResponse{}
+	for _, feat := range mres.Features {
+		out.Features = append(out.Features, &geocodepb.Feature{
+			PlaceName: feat.PlaceName,
+			Relevance: feat.Relevance,
+			Center:    feat.Center,
+		})
+	}
+	return out
+}
+
+// serveGRPC starts the gRPC server on grpcAddr and blocks until it exits.
+// It shares OpenCensus tracing/metrics with the HTTP server via ocgrpc.
+func serveGRPC(grpcAddr string) {
+	if err := view.Register(ocgrpc.DefaultServerViews...); err != nil {
+		log.Fatalf("Failed to register gRPC server views: %v", err)
+	}
+	ln, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to bind gRPC address: %q error: %v", grpcAddr, err)
+	}
+	srv := grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{}))
+	geocodepb.RegisterGeocodeServiceServer(srv, &geocodeServer{})
+	log.Printf("gRPC address: %s", ln.Addr().String())
+	if err := srv.Serve(ln); err != nil {
+		log.Fatalf("Failed to serve gRPC: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/groupcache"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+)
+
+// keyCount tracks how many times a key has been requested within the
+// current flush window. Its own mutex guards count/last; the map it lives
+// in gains keys through record and is never pruned key-by-key, only
+// swapped out wholesale by flush.
+type keyCount struct {
+	mu    sync.Mutex
+	count int64
+	last  time.Time
+}
+
+// prefetcher counts lookups per key and, on a timer, re-issues group.Get
+// for the topN keys with the highest counts.
+//
+// Access counts are tracked in two rotating sync.Maps: "current" absorbs
+// new accesses while "previous" (the prior window) is what ranking and
+// prefetching operate on. Both fields are atomic.Pointers so record() and
+// hottest() never need to coordinate with flush()'s swap.
+type prefetcher struct {
+	groups map[string]*groupcache.Group
+
+	current  atomic.Pointer[sync.Map]
+	previous atomic.Pointer[sync.Map]
+
+	topN     int
+	interval time.Duration
+}
+
+func newPrefetcher(groups map[string]*groupcache.Group, topN int, interval time.Duration) *prefetcher {
+	p := &prefetcher{
+		groups:   groups,
+		topN:     topN,
+		interval: interval,
+	}
+	p.current.Store(new(sync.Map))
+	p.previous.Store(new(sync.Map))
+	return p
+}
+
+// record notes that groupName/key was just requested. Safe for concurrent
+// use.
+func (p *prefetcher) record(groupName, key string) {
+	fullKey := groupName + "\x00" + key
+	v, _ := p.current.Load().LoadOrStore(fullKey, &keyCount{})
+	kc := v.(*keyCount)
+	kc.mu.Lock()
+	kc.count++
+	kc.last = time.Now()
+	kc.mu.Unlock()
+}
+
+type hotKey struct {
+	GroupName string    `json:"group_name"`
+	Key       string    `json:"key"`
+	Count     int64     `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// run drives the rotate-and-prefetch loop until ctx is canceled.
+func (p *prefetcher) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.flush(ctx)
+		}
+	}
+}
+
+// flush rotates the current map into previous and re-issues group.Get for
+// the hottest keys in what is now the previous window.
+func (p *prefetcher) flush(ctx context.Context) {
+	p.previous.Store(p.current.Swap(new(sync.Map)))
+
+	hot := p.hottest(p.topN)
+	for _, hk := range hot {
+		group := p.groups[hk.GroupName]
+		if group == nil {
+			continue
+		}
+		ctx, span := trace.StartSpan(ctx, "prefetch")
+		var data []byte
+		err := group.Get(ctx, hk.Key, groupcache.AllocatingByteSliceSink(&data))
+		span.End()
+		tagged, tagErr := tag.New(ctx, tag.Upsert(keyGroupName, hk.GroupName))
+		if tagErr != nil {
+			tagged = ctx
+		}
+		if err != nil {
+			log.Printf("prefetch: failed to warm %s/%q: %v", hk.GroupName, hk.Key, err)
+			stats.Record(tagged, mPrefetchMiss.M(1))
+			continue
+		}
+		stats.Record(tagged, mPrefetchHit.M(1))
+	}
+}
+
+// hottest returns the n keys with the highest request counts from the
+// previous (fully settled) window.
+func (p *prefetcher) hottest(n int) []hotKey {
+	var all []hotKey
+	p.previous.Load().Range(func(k, v interface{}) bool {
+		fullKey := k.(string)
+		kc := v.(*keyCount)
+		groupName, key := splitPrefetchKey(fullKey)
+		kc.mu.Lock()
+		count, last := kc.count, kc.last
+		kc.mu.Unlock()
+		all = append(all, hotKey{GroupName: groupName, Key: key, Count: count, LastSeen: last})
+		return true
+	})
+	sort.Slice(all, func(i, j int) bool { return all[i].Count > all[j].Count })
+	if len(all) > n {
+		all = all[:n]
+	}
+	return all
+}
+
+func splitPrefetchKey(fullKey string) (groupName, key string) {
+	for i := 0; i < len(fullKey); i++ {
+		if fullKey[i] == 0 {
+			return fullKey[:i], fullKey[i+1:]
+		}
+	}
+	return fullKey, ""
+}
+
+// prefetchStatusHandler serves the current hot-key list over HTTP for
+// admins/operators to inspect.
+func (p *prefetcher) prefetchStatusHandler(w http.ResponseWriter, r *http.Request) {
+	hot := p.hottest(p.topN)
+	blob, err := json.Marshal(hot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
+
+var (
+	keyGroupName = tag.MustNewKey("group_name")
+
+	mPrefetchHit  = stats.Int64("mapboxsearch/prefetch_hits", "Number of successful prefetch warmings", stats.UnitDimensionless)
+	mPrefetchMiss = stats.Int64("mapboxsearch/prefetch_misses", "Number of failed prefetch warmings", stats.UnitDimensionless)
+
+	prefetchHitsView = &view.View{
+		Name:        "mapboxsearch/prefetch_hits",
+		Measure:     mPrefetchHit,
+		Description: "Count of hot keys successfully re-warmed by the prefetcher",
+		TagKeys:     []tag.Key{keyGroupName},
+		Aggregation: view.Count(),
+	}
+	prefetchMissesView = &view.View{
+		Name:        "mapboxsearch/prefetch_misses",
+		Measure:     mPrefetchMiss,
+		Description: "Count of hot keys the prefetcher failed to re-warm",
+		TagKeys:     []tag.Key{keyGroupName},
+		Aggregation: view.Count(),
+	}
+)
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/groupcache"
+)
+
+func TestPrefetcherRecordAndHottest(t *testing.T) {
+	p := newPrefetcher(nil, 2, time.Hour)
+
+	p.record("address_lookup", "palo alto")
+	p.record("address_lookup", "palo alto")
+	p.record("address_lookup", "palo alto")
+	p.record("address_lookup", "reno")
+	p.record("lat_lon_lookup", "37.4,-122.1")
+
+	// hottest reads from the previous window, which is empty until flush
+	// rotates current into it.
+	if hot := p.hottest(10); len(hot) != 0 {
+		t.Fatalf("got %d hot keys before any flush, want 0", len(hot))
+	}
+
+	p.flush(context.Background())
+
+	hot := p.hottest(10)
+	if len(hot) != 3 {
+		t.Fatalf("got %d hot keys, want 3", len(hot))
+	}
+	if hot[0].GroupName != "address_lookup" || hot[0].Key != "palo alto" || hot[0].Count != 3 {
+		t.Fatalf("got top hot key %+v, want address_lookup/palo alto with count 3", hot[0])
+	}
+}
+
+func TestPrefetcherHottestTopN(t *testing.T) {
+	p := newPrefetcher(nil, 1, time.Hour)
+
+	p.record("address_lookup", "a")
+	p.record("address_lookup", "b")
+	p.record("address_lookup", "b")
+
+	p.flush(context.Background())
+
+	hot := p.hottest(p.topN)
+	if len(hot) != 1 {
+		t.Fatalf("got %d hot keys, want topN=1", len(hot))
+	}
+	if hot[0].Key != "b" {
+		t.Fatalf("got top key %q, want %q", hot[0].Key, "b")
+	}
+}
+
+func TestPrefetcherFlushRotatesWindow(t *testing.T) {
+	p := newPrefetcher(map[string]*groupcache.Group{}, 10, time.Hour)
+
+	p.record("address_lookup", "palo alto")
+	p.flush(context.Background())
+
+	// A key recorded after the first flush belongs to the new "current"
+	// window and shouldn't show up until the next flush rotates it in.
+	p.record("address_lookup", "reno")
+	hot := p.hottest(10)
+	if len(hot) != 1 || hot[0].Key != "palo alto" {
+		t.Fatalf("got %+v, want only the pre-flush key still in the previous window", hot)
+	}
+
+	p.flush(context.Background())
+	hot = p.hottest(10)
+	if len(hot) != 1 || hot[0].Key != "reno" {
+		t.Fatalf("got %+v, want only the post-flush key after rotating again", hot)
+	}
+}
@@ -14,9 +14,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/groupcache"
-	"github.com/orijtech/mapbox"
 	"github.com/orijtech/otils"
 
 	"contrib.go.opencensus.io/exporter/stackdriver"
@@ -28,6 +28,16 @@ import (
 func main() {
 	addr := flag.String("addr", ":0", "the address on which to run the HTTPPool")
 	peersCSV := flag.String("peers-csv", "", "the peers' full HTTP addresses separated by commas e.g http://localhost:9877,http://localhost:localhost:9878")
+	backendsCSV := flag.String("backends", "mapbox", "comma separated geocoding backends to try in order e.g mapbox,nominatim,maxmind,google")
+	prefetchTopN := flag.Int("prefetch-top-n", 100, "the number of hottest keys to re-warm on every prefetch cadence")
+	prefetchInterval := flag.Duration("prefetch-interval", 30*time.Minute, "how often to flush lookup counters and re-warm the hottest keys")
+	cacheDBPath := flag.String("cache-db", "mapboxsearch-cache.db", "path to the BoltDB file backing the persistent cache tier")
+	cacheTTL := flag.Duration("cache-ttl", 24*time.Hour, "how long a persisted cache entry remains valid before it is treated as a miss")
+	importJSONCachePath := flag.String("import-json-cache", "", "path to a legacy newline-delimited-JSON cache file to migrate into -cache-db on startup")
+	grpcAddr := flag.String("grpc-addr", ":0", "the address on which to run the gRPC GeocodeService, alongside the HTTP API")
+	discoveryMode := flag.String("discovery", "static", "how to discover peers: static, consul or k8s")
+	discoveryService := flag.String("discovery-service", "mapbox-search", "the Consul service name or Kubernetes Service name to watch for peer membership, ignored in static mode")
+	discoveryNamespace := flag.String("discovery-namespace", "", "the Kubernetes namespace to watch in k8s mode; defaults to the pod's own namespace")
 	flag.Parse()
 
 	if err := enableOpenCensus(); err != nil {
@@ -41,8 +51,33 @@ func main() {
 	}
 	defer ln.Close()
 
+	geocoder, err := newGeocoderChain(*backendsCSV)
+	if err != nil {
+		log.Fatalf("Failed to initialize geocoding backends: %v", err)
+	}
+
+	persistentCache, err = newDiskCache(*cacheDBPath, *cacheTTL, addressLookupName, latLonLookupName)
+	if err != nil {
+		log.Fatalf("Failed to open persistent cache: %v", err)
+	}
+	defer persistentCache.Close()
+
+	if *importJSONCachePath != "" {
+		n, err := importJSONCacheFile(persistentCache, *importJSONCachePath)
+		if err != nil {
+			log.Fatalf("Failed to import legacy JSON cache %q: %v", *importJSONCachePath, err)
+		}
+		log.Printf("Imported %d entries from legacy JSON cache %q", n, *importJSONCachePath)
+	}
+
 	// Register groups
-	registerGroups()
+	registerGroups(geocoder)
+
+	hotKeys = newPrefetcher(map[string]*groupcache.Group{
+		addressLookupName: byNameGroup,
+		latLonLookupName:  byLatLonGroup,
+	}, *prefetchTopN, *prefetchInterval)
+	go hotKeys.run(context.Background())
 
 	httpAddr := fmt.Sprintf("http://%s", ln.Addr().String())
 	pool = groupcache.NewHTTPPoolOpts(httpAddr, &groupcache.HTTPPoolOptions{BasePath: "/"})
@@ -50,12 +85,27 @@ func main() {
 		pool.Set(peersList...)
 	}
 
+	discoverer, err := newDiscoverer(*discoveryMode, peersList, *discoveryService, *discoveryNamespace)
+	if err != nil {
+		log.Fatalf("Failed to initialize peer discovery: %v", err)
+	}
+	go func() {
+		if err := discoverer.watch(context.Background(), reconcilePeers); err != nil {
+			log.Printf("Peer discovery watcher exited: %v", err)
+		}
+	}()
+
+	go serveGRPC(*grpcAddr)
+
 	log.Printf("HTTP address: %s", httpAddr)
 	mux := http.NewServeMux()
 	mux.Handle("/", pool)
 	mux.HandleFunc("/latlon", byLatLon)
 	mux.HandleFunc("/name", byName)
 	mux.HandleFunc("/setpeers", setPeers)
+	mux.HandleFunc("/batch", batchHandler)
+	mux.HandleFunc("/admin/prefetch", hotKeys.prefetchStatusHandler)
+	mux.HandleFunc("/cache/stats", persistentCache.cacheStatsHandler)
 
 	h := &ochttp.Handler{Handler: mux}
 	if err := http.Serve(ln, h); err != nil {
@@ -103,6 +153,8 @@ func lookup(ctx context.Context, groupName string, w http.ResponseWriter, r *htt
 		return
 	}
 
+	hotKeys.record(groupName, key)
+
 	var data []byte
 	if err := group.Get(ctx, key, groupcache.AllocatingByteSliceSink(&data)); err != nil {
 		log.Printf("Lookup error: %v key: %q\n", err, key)
@@ -115,6 +167,10 @@ func lookup(ctx context.Context, groupName string, w http.ResponseWriter, r *htt
 var poolMu sync.Mutex
 var pool *groupcache.HTTPPool
 
+var hotKeys *prefetcher
+
+var persistentCache *diskCache
+
 type peers struct {
 	GroupName string   `json:"group_name"`
 	Peers     []string `json:"peers"`
@@ -146,28 +202,22 @@ const (
 	latLonLookupName  = "lat_lon_lookup"
 )
 
-var mapboxClient *mapbox.Client
-
-func init() {
-	var err error
-	mapboxClient, err = mapbox.NewClient()
-	if err != nil {
-		log.Fatalf("Failed to create a mapbox client: %v", err)
-	}
-}
-
 var (
 	byNameGroup, byLatLonGroup *groupcache.Group
 )
 
-func registerGroups() {
+func registerGroups(geocoder Geocoder) {
 	// For address lookup
 	byNameGroup = groupcache.NewGroup(addressLookupName, 1<<31, groupcache.GetterFunc(func(ctx context.Context, placeName string, sink groupcache.Sink) error {
 		ctx, span := trace.StartSpan(ctx, "name_lookup")
 		defer span.End()
 
 		// The key is a string descriptive of a place for example "Olduvai Gorge" or "Palo Alto"
-		match, err := mapboxClient.LookupPlace(ctx, placeName)
+		if cached, ok, err := persistentCache.Get(addressLookupName, placeName); err == nil && ok {
+			sink.SetBytes(cached)
+			return nil
+		}
+		match, err := lookupPlaceOrIP(ctx, geocoder, placeName)
 		if err != nil {
 			return err
 		}
@@ -175,6 +225,9 @@ func registerGroups() {
 		if err != nil {
 			return err
 		}
+		if err := persistentCache.Set(addressLookupName, placeName, blob); err != nil {
+			log.Printf("persistentCache: failed to write through %q: %v", placeName, err)
+		}
 		sink.SetBytes(blob)
 		return nil
 	}))
@@ -197,7 +250,11 @@ func registerGroups() {
 		if err != nil {
 			return fmt.Errorf("failed to parse longitude: %v", err)
 		}
-		match, err := mapboxClient.LookupLatLon(ctx, lat, lon)
+		if cached, ok, err := persistentCache.Get(latLonLookupName, latLonCSV); err == nil && ok {
+			sink.SetBytes(cached)
+			return nil
+		}
+		match, err := geocoder.LookupLatLon(ctx, lat, lon)
 		if err != nil {
 			return err
 		}
@@ -205,6 +262,9 @@ func registerGroups() {
 		if err != nil {
 			return err
 		}
+		if err := persistentCache.Set(latLonLookupName, latLonCSV, blob); err != nil {
+			log.Printf("persistentCache: failed to write through %q: %v", latLonCSV, err)
+		}
 		sink.SetBytes(blob)
 		return nil
 	}))
@@ -228,6 +288,14 @@ func enableOpenCensus() error {
 	if err := view.Register(groupcache.AllViews...); err != nil {
 		return fmt.Errorf("failed to register groupcache views: %v", err)
 	}
+	// Enable prefetcher metrics
+	if err := view.Register(prefetchHitsView, prefetchMissesView); err != nil {
+		return fmt.Errorf("failed to register prefetch views: %v", err)
+	}
+	// Enable peer discovery metrics
+	if err := view.Register(peerChurnView); err != nil {
+		return fmt.Errorf("failed to register peer discovery views: %v", err)
+	}
 	view.RegisterExporter(sd)
 	trace.RegisterExporter(sd)
 	return nil